@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestReaderWriterRoundTrip(t *testing.T) {
+	records := []Record{
+		{"name": "Alice", "age": "30"},
+		{"name": "Bob", "age": "25"},
+	}
+
+	for ext := range writers {
+		ext := ext
+
+		t.Run(ext, func(t *testing.T) {
+			writer, ok := writers[ext]
+			if !ok {
+				t.Fatalf("no writer registered for %q", ext)
+			}
+
+			reader, ok := readers[ext]
+			if !ok {
+				t.Fatalf("no reader registered for %q", ext)
+			}
+
+			path := filepath.Join(t.TempDir(), "records"+ext)
+
+			if err := writer.Write(path, records); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+
+			got, err := reader.Read(path)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+
+			if len(got) != len(records) {
+				t.Fatalf("Read returned %d records, want %d", len(got), len(records))
+			}
+
+			for i, want := range records {
+				for k, v := range want {
+					gv := fmt.Sprintf("%v", got[i][k])
+					if gv != v {
+						t.Errorf("record %d field %q = %q, want %q", i, k, gv, v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCsvReaderRequiresHeaderAndData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.csv")
+
+	if err := writers[".csv"].Write(path, nil); err == nil {
+		t.Fatal("Write with no records should fail, got nil error")
+	}
+}
+
+func TestCsvWriterUnionsHeadersAcrossRecords(t *testing.T) {
+	records := []Record{
+		{"name": "Alice"},
+		{"name": "Bob", "email": "bob@example.com"},
+	}
+
+	path := filepath.Join(t.TempDir(), "records.csv")
+
+	if err := writers[".csv"].Write(path, records); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := readers[".csv"].Read(path)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d records, want 2", len(got))
+	}
+
+	if email, ok := got[1]["email"]; !ok || email != "bob@example.com" {
+		t.Errorf("second record email = %v, want %q", email, "bob@example.com")
+	}
+
+	if _, ok := got[0]["email"]; !ok {
+		t.Error("first record should still have an (empty) email column from the union header")
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// withTestServer points cfg and the shared httpClient at server for the
+// duration of the test, restoring the previous global state afterwards.
+func withTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+
+	origURL := cfg.URL
+	origClient := httpClient
+	origRetries := maxRetries
+
+	cfg.URL = server.URL
+	configureHTTPClient(1, 5*time.Second)
+
+	t.Cleanup(func() {
+		server.Close()
+		cfg.URL = origURL
+		httpClient = origClient
+		maxRetries = origRetries
+	})
+
+	return server
+}
+
+func TestSendRequestRetriesOnRetryableStatus(t *testing.T) {
+	var attempts int32
+
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	configureRetries(5)
+
+	var resp struct {
+		Ok bool `json:"ok"`
+	}
+
+	if err := sendRequest("GET", "/ping", "", nil, &resp); err != nil {
+		t.Fatalf("sendRequest: %v", err)
+	}
+
+	if !resp.Ok {
+		t.Error("expected the final 200 response body to be decoded")
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("server received %d attempts, want 3 (2 failures + 1 success)", got)
+	}
+}
+
+func TestSendRequestGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int32
+
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	configureRetries(2)
+
+	if err := sendRequest("GET", "/ping", "", nil, nil); err == nil {
+		t.Fatal("expected an error once retries are exhausted, got nil")
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("server received %d attempts, want 3 (1 initial + 2 retries)", got)
+	}
+}
+
+func TestSendRequestHonorsRetryAfter(t *testing.T) {
+	var attempts int32
+	var firstAttempt, secondAttempt time.Time
+
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			firstAttempt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		secondAttempt = time.Now()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	configureRetries(3)
+
+	if err := sendRequest("GET", "/ping", "", nil, nil); err != nil {
+		t.Fatalf("sendRequest: %v", err)
+	}
+
+	if gap := secondAttempt.Sub(firstAttempt); gap < 900*time.Millisecond {
+		t.Errorf("retry fired after %v, want it to wait out the 1s Retry-After header", gap)
+	}
+}
+
+func TestCreateRecordsBatchSendsAPIPrefixedURLs(t *testing.T) {
+	var captured BatchRequestBody
+
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/batch" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &captured); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		results := make([]BatchResult, len(captured.Requests))
+		for i, sub := range captured.Requests {
+			b, _ := json.Marshal(sub.Body)
+			results[i] = BatchResult{Status: 200, Body: b}
+		}
+
+		respBytes, _ := json.Marshal(results)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respBytes)
+	})
+
+	records := []Record{{"name": "a"}, {"name": "b"}}
+
+	created, err := createRecordsBatch(records, "posts", "tok")
+	if err != nil {
+		t.Fatalf("createRecordsBatch: %v", err)
+	}
+
+	if len(created) != len(records) {
+		t.Fatalf("got %d created records, want %d", len(created), len(records))
+	}
+
+	if len(captured.Requests) != len(records) {
+		t.Fatalf("server received %d sub-requests, want %d", len(captured.Requests), len(records))
+	}
+
+	for _, sub := range captured.Requests {
+		if want := "/api/collections/posts/records"; sub.Url != want {
+			t.Errorf("sub-request URL = %q, want %q", sub.Url, want)
+		}
+	}
+}
+
+func TestFetchAllRecordsStopsAtTotalPages(t *testing.T) {
+	const totalPages = 3
+
+	var requestedPages []string
+
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		requestedPages = append(requestedPages, page)
+
+		pageNum, _ := strconv.Atoi(page)
+
+		resp := map[string]interface{}{
+			"items":      []Record{{"id": fmt.Sprintf("rec-%d", pageNum)}},
+			"page":       pageNum,
+			"perPage":    500,
+			"totalItems": totalPages,
+			"totalPages": totalPages,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	records, err := fetchAllRecords("posts", "", "", "tok")
+	if err != nil {
+		t.Fatalf("fetchAllRecords: %v", err)
+	}
+
+	if len(records) != totalPages {
+		t.Fatalf("got %d records, want %d", len(records), totalPages)
+	}
+
+	if len(requestedPages) != totalPages {
+		t.Errorf("requested %d pages, want exactly %d (should stop at totalPages)", len(requestedPages), totalPages)
+	}
+}
+
+func TestLoadRelationCachePagesThroughAll(t *testing.T) {
+	const totalPages = 3
+	const perPage = 2
+
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		pageNum, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		items := make([]Record, perPage)
+		for i := range items {
+			items[i] = Record{"id": fmt.Sprintf("rec-%d-%d", pageNum, i)}
+		}
+
+		resp := map[string]interface{}{
+			"items":      items,
+			"totalPages": totalPages,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	cache, err := loadRelationCache("posts", "tok", 0)
+	if err != nil {
+		t.Fatalf("loadRelationCache: %v", err)
+	}
+
+	if got := len(cache.ids); got != totalPages*perPage {
+		t.Errorf("cache has %d ids, want %d", got, totalPages*perPage)
+	}
+}
+
+func TestLoadRelationCacheRespectsPoolSize(t *testing.T) {
+	const totalPages = 5
+	const perPage = 2
+	const poolSize = 3
+
+	withTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		pageNum, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		items := make([]Record, perPage)
+		for i := range items {
+			items[i] = Record{"id": fmt.Sprintf("rec-%d-%d", pageNum, i)}
+		}
+
+		resp := map[string]interface{}{
+			"items":      items,
+			"totalPages": totalPages,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	cache, err := loadRelationCache("posts", "tok", poolSize)
+	if err != nil {
+		t.Fatalf("loadRelationCache: %v", err)
+	}
+
+	if got := len(cache.ids); got != poolSize {
+		t.Errorf("cache has %d ids, want exactly %d (poolSize truncation)", got, poolSize)
+	}
+}
@@ -2,8 +2,8 @@ package main
 
 import (
 	"bytes"
-	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/ilyakaznacheev/cleanenv"
@@ -13,10 +13,14 @@ import (
 	"io"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -52,8 +56,63 @@ type Config struct {
 	Password string `yaml:"password" env:"PASSWORD"`
 }
 
+type BatchSubRequest struct {
+	Method string      `json:"method"`
+	Url    string      `json:"url"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+type BatchRequestBody struct {
+	Requests []BatchSubRequest `json:"requests"`
+}
+
+type BatchResult struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
 var cfg Config
 
+// requestFlags returns the flags shared by every command that talks to
+// PocketBase, controlling the HTTP client's timeout and retry behavior.
+func requestFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "HTTP request timeout",
+			Value: 30 * time.Second,
+		},
+		&cli.IntFlag{
+			Name:  "max-retries",
+			Usage: "Max retry attempts for rate-limited (429) or transient (502/503/504) responses",
+			Value: 3,
+		},
+	}
+}
+
+// httpClient is shared across all requests so connections get reused
+// instead of a fresh Transport per call. configureHTTPClient tunes it for
+// the concurrency a command runs with.
+var httpClient = &http.Client{}
+
+// configureHTTPClient sizes the shared client's connection pool for
+// maxIdleConnsPerHost concurrent workers so each one gets a reusable
+// keep-alive connection instead of dialing a new one per request, and
+// bounds every request by timeout so a hung request can't stall the
+// whole pipeline.
+func configureHTTPClient(maxIdleConnsPerHost int, timeout time.Duration) {
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = 1
+	}
+
+	httpClient = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		},
+	}
+}
+
 func init() {
 	slog.SetDefault(slog.New(tint.NewHandler(os.Stderr, &tint.Options{
 		Level:      slog.LevelDebug,
@@ -68,6 +127,13 @@ const (
 	Dependent = "dependent"
 	Relation  = "relation"
 	Custom    = "custom"
+	// RelationExisting behaves like Relation but is backed by a
+	// RelationCache, so it costs one paginated fetch per collection
+	// instead of one GET per generated record.
+	RelationExisting = "relation_existing"
+	// RelationSelf picks an id created earlier in the current run,
+	// for self-referential schemas such as parent/child comment trees.
+	RelationSelf = "relation_self"
 )
 
 func main() {
@@ -89,7 +155,7 @@ func main() {
 			{
 				Name:  "seed",
 				Usage: "seed the database with records",
-				Flags: []cli.Flag{
+				Flags: append([]cli.Flag{
 					&cli.StringFlag{
 						Name:     "config",
 						Required: true,
@@ -111,13 +177,25 @@ func main() {
 						Value:       10,
 						DefaultText: "10",
 					},
-					// TODO
 					&cli.IntFlag{
 						Name:  "batch",
-						Usage: "Number of records to seed in a batch",
+						Usage: "Number of records to submit per /api/batch call (1 disables batching)",
+						Value: 1,
 					},
-				},
+					&cli.IntFlag{
+						Name:  "concurrency",
+						Usage: "Number of worker goroutines creating records concurrently",
+						Value: 4,
+					},
+					&cli.IntFlag{
+						Name:  "relation-pool-size",
+						Usage: "Max ids to cache per relation_existing collection (0 = cache all)",
+					},
+				}, requestFlags()...),
 				Action: func(cCtx *cli.Context) error {
+					configureHTTPClient(cCtx.Int("concurrency"), cCtx.Duration("timeout"))
+					configureRetries(cCtx.Int("max-retries"))
+
 					err := cleanenv.ReadConfig(cCtx.String("config"), &cfg)
 					if err != nil {
 						slog.Error("reading config", "error", err)
@@ -137,13 +215,16 @@ func main() {
 
 					token := authResponse.Token
 
+					relations := NewRelationCacheStore(token, cCtx.Int("relation-pool-size"))
+					self := NewRelationCache()
+
 					items := make(chan Record)
 
 					slog.Info("batch size", "batch", cCtx.Int("batch"))
 
 					go func() {
 						for i := 0; i < cCtx.Int("count"); i++ {
-							r, err := fakeRecord(schema, token)
+							r, err := fakeRecord(schema, token, relations, self)
 							if err != nil {
 								slog.Error("generating fake record", "error", err)
 								return
@@ -155,21 +236,7 @@ func main() {
 						close(items)
 					}()
 
-					records := make(chan Record)
-
-					go func() {
-						for item := range items {
-							r, err := createRecord(item, cCtx.String("collection"), token)
-							if err != nil {
-								slog.Error("creating record", "error", err)
-								return
-							}
-
-							records <- r
-						}
-
-						close(records)
-					}()
+					records := createRecords(items, cCtx.String("collection"), token, cCtx.Int("batch"), cCtx.Int("concurrency"), self)
 
 					for r := range records {
 						slog.Info("record created", "record", r)
@@ -181,7 +248,7 @@ func main() {
 			{
 				Name:  "import",
 				Usage: "import records from a file",
-				Flags: []cli.Flag{
+				Flags: append([]cli.Flag{
 					&cli.StringFlag{
 						Name:     "config",
 						Required: true,
@@ -197,8 +264,21 @@ func main() {
 						Usage:    "Load records from `FILE`",
 						Required: true,
 					},
-				},
+					&cli.IntFlag{
+						Name:  "batch",
+						Usage: "Number of records to submit per /api/batch call (1 disables batching)",
+						Value: 1,
+					},
+					&cli.IntFlag{
+						Name:  "concurrency",
+						Usage: "Number of worker goroutines creating records concurrently",
+						Value: 4,
+					},
+				}, requestFlags()...),
 				Action: func(cCtx *cli.Context) error {
+					configureHTTPClient(cCtx.Int("concurrency"), cCtx.Duration("timeout"))
+					configureRetries(cCtx.Int("max-retries"))
+
 					err := cleanenv.ReadConfig(cCtx.String("config"), &cfg)
 					if err != nil {
 						slog.Error("Failed to read config", "error", err)
@@ -214,45 +294,104 @@ func main() {
 
 					token := authResponse.Token
 
-					var items []Record
-
-					switch fileExt {
-					case ".json":
-						items = ReadJson(filePath)
-						if err != nil {
-							slog.Error("reading JSON records", "error", err)
-							return err
-						}
-					case ".csv":
-						items = ReadCsv(filePath)
-					default:
+					reader, ok := readers[fileExt]
+					if !ok {
 						return fmt.Errorf("unsupported file type: %s", fileExt)
 					}
 
+					items, err := reader.Read(filePath)
+					if err != nil {
+						slog.Error("reading records", "error", err)
+						return err
+					}
+
 					slog.Info("importing records", "count", len(items))
 
-					records := make(chan Record)
+					itemsCh := make(chan Record)
 
 					go func() {
 						for _, item := range items {
 							slog.Debug("processing record", "record", item)
-
-							r, err := createRecord(item, cCtx.String("collection"), token)
-							if err != nil {
-								slog.Error("creating record", "error", err)
-								return
-							}
-
-							records <- r
+							itemsCh <- item
 						}
 
-						close(records)
+						close(itemsCh)
 					}()
 
+					records := createRecords(itemsCh, cCtx.String("collection"), token, cCtx.Int("batch"), cCtx.Int("concurrency"), nil)
+
 					for r := range records {
 						slog.Info("record created", "record", r)
 					}
 
+					return nil
+				},
+			},
+			{
+				Name:  "dump",
+				Usage: "dump a collection to a file",
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{
+						Name:     "config",
+						Required: true,
+						Usage:    "Load configuration from `FILE`",
+					},
+					&cli.StringFlag{
+						Name:     "collection",
+						Usage:    "Collection to dump",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "file",
+						Usage:    "Write records to `FILE`",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "filter",
+						Usage: "PocketBase filter expression to apply",
+					},
+					&cli.StringFlag{
+						Name:  "fields",
+						Usage: "Comma separated list of fields to dump",
+					},
+				}, requestFlags()...),
+				Action: func(cCtx *cli.Context) error {
+					configureHTTPClient(1, cCtx.Duration("timeout"))
+					configureRetries(cCtx.Int("max-retries"))
+
+					err := cleanenv.ReadConfig(cCtx.String("config"), &cfg)
+					if err != nil {
+						slog.Error("reading config", "error", err)
+					}
+
+					authResponse, err := authenticateAdmin(cfg.Email, cfg.Password)
+					if err != nil {
+						log.Fatalf("Authentication failed: %v", err)
+					}
+
+					token := authResponse.Token
+
+					records, err := fetchAllRecords(cCtx.String("collection"), cCtx.String("filter"), cCtx.String("fields"), token)
+					if err != nil {
+						slog.Error("fetching records", "error", err)
+						return err
+					}
+
+					slog.Info("dumping records", "count", len(records))
+
+					filePath := cCtx.String("file")
+					fileExt := strings.ToLower(filepath.Ext(filePath))
+
+					writer, ok := writers[fileExt]
+					if !ok {
+						return fmt.Errorf("unsupported file type: %s", fileExt)
+					}
+
+					if err := writer.Write(filePath, records); err != nil {
+						slog.Error("writing records", "error", err)
+						return err
+					}
+
 					return nil
 				},
 			},
@@ -264,7 +403,11 @@ func main() {
 	}
 }
 
-func fakeRecord(schema Schema, token string) (Record, error) {
+// fakeRecord generates one record from schema. relations resolves
+// RelationExisting fields against a cached pool of ids per collection, and
+// self resolves RelationSelf fields against ids created earlier in the
+// current run; both may be nil if the schema uses neither field type.
+func fakeRecord(schema Schema, token string, relations *RelationCacheStore, self *RelationCache) (Record, error) {
 	r := Record{}
 
 	err := gofakeit.Seed(0)
@@ -294,6 +437,26 @@ func fakeRecord(schema Schema, token string) (Record, error) {
 
 			r[field.Name] = rr["id"]
 		}
+
+		if field.Type == RelationExisting {
+			cache, err := relations.Get(field.Value)
+			if err != nil {
+				return Record{}, err
+			}
+
+			id, ok := cache.Random()
+			if !ok {
+				return Record{}, fmt.Errorf("no existing records found in collection %q for relation_existing field %q", field.Value, field.Name)
+			}
+
+			r[field.Name] = id
+		}
+
+		if field.Type == RelationSelf {
+			if id, ok := self.Random(); ok {
+				r[field.Name] = id
+			}
+		}
 	}
 
 	return r, nil
@@ -322,6 +485,176 @@ func createRecord(record interface{}, col string, token string) (Record, error)
 	return r, nil
 }
 
+// createRecords fans out record creation across concurrency worker
+// goroutines, each reading from items and creating records in col. Each
+// worker batches its own items into groups of batchSize before submitting
+// them through a single /api/batch call; batchSize <= 1 falls back to one
+// createRecord call per item, identical to the pre-batching behavior. If
+// self is non-nil, every created record's id is added to it so later
+// generated items can resolve relation_self fields against it.
+// records is closed once every worker has drained items.
+func createRecords(items <-chan Record, col string, token string, batchSize int, concurrency int, self *RelationCache) <-chan Record {
+	records := make(chan Record)
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	remember := func(r Record) {
+		if self == nil {
+			return
+		}
+
+		if id, ok := r["id"].(string); ok {
+			self.Add(id)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+
+			if batchSize <= 1 {
+				for item := range items {
+					r, err := createRecord(item, col, token)
+					if err != nil {
+						slog.Error("creating record", "error", err)
+						return
+					}
+
+					remember(r)
+					records <- r
+				}
+
+				return
+			}
+
+			buf := make([]Record, 0, batchSize)
+
+			flush := func() {
+				if len(buf) == 0 {
+					return
+				}
+
+				rs, err := createRecordsBatch(buf, col, token)
+				if err != nil {
+					slog.Error("creating batch", "error", err)
+				}
+
+				for _, r := range rs {
+					remember(r)
+					records <- r
+				}
+
+				buf = buf[:0]
+			}
+
+			for item := range items {
+				buf = append(buf, item)
+
+				if len(buf) == batchSize {
+					flush()
+				}
+			}
+
+			flush()
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(records)
+	}()
+
+	return records
+}
+
+// createRecordsBatch submits records as a single POST /api/batch call and
+// returns the successfully created records. PocketBase responds with 200
+// even when some sub-requests fail, so per-item failures are logged rather
+// than returned as a top-level error.
+func createRecordsBatch(records []Record, col string, token string) ([]Record, error) {
+	subRequests := make([]BatchSubRequest, len(records))
+	for i, r := range records {
+		subRequests[i] = BatchSubRequest{
+			Method: "POST",
+			Url:    "/api/collections/" + col + "/records",
+			Body:   r,
+		}
+	}
+
+	var results []BatchResult
+
+	err := sendRequest("POST", "/batch", token, BatchRequestBody{Requests: subRequests}, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]Record, 0, len(results))
+
+	for i, res := range results {
+		if res.Status < 200 || res.Status >= 300 {
+			slog.Error("batch sub-request failed", "index", i, "status", res.Status, "body", string(res.Body))
+			continue
+		}
+
+		var r Record
+		if err := json.Unmarshal(res.Body, &r); err != nil {
+			slog.Error("unmarshaling batch sub-response", "index", i, "error", err)
+			continue
+		}
+
+		created = append(created, r)
+	}
+
+	return created, nil
+}
+
+// fetchAllRecords pages through /api/collections/{col}/records with
+// perPage=500 until totalPages is exhausted, optionally narrowed by a
+// PocketBase filter expression and/or a comma separated fields list.
+func fetchAllRecords(col string, filter string, fields string, token string) ([]Record, error) {
+	const perPage = 500
+
+	var all []Record
+
+	for page := 1; ; page++ {
+		var respBody struct {
+			Items      []Record `json:"items"`
+			Page       int      `json:"page"`
+			PerPage    int      `json:"perPage"`
+			TotalItems int      `json:"totalItems"`
+			TotalPages int      `json:"totalPages"`
+		}
+
+		q := url.Values{}
+		q.Set("page", strconv.Itoa(page))
+		q.Set("perPage", strconv.Itoa(perPage))
+		if filter != "" {
+			q.Set("filter", filter)
+		}
+		if fields != "" {
+			q.Set("fields", fields)
+		}
+
+		err := sendRequest("GET", "/collections/"+col+"/records?"+q.Encode(), token, nil, &respBody)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, respBody.Items...)
+
+		if page >= respBody.TotalPages {
+			break
+		}
+	}
+
+	return all, nil
+}
+
 func getRandomRecord(col string, token string) (Record, error) {
 	var respBody struct {
 		Items      []Record `json:"items"`
@@ -354,20 +687,41 @@ func sendRequest(method, url string, token string, reqBody interface{}, respBody
 		}
 	}
 
-	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s%s", cfg.URL, "api", url), bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
+	var resp *http.Response
 
-	req.Header.Set("Content-Type", "application/json")
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
-	}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, fmt.Sprintf("%s/%s%s", cfg.URL, "api", url), bytes.NewBuffer(jsonData))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %v", err)
+		}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err = httpClient.Do(req)
+		if err != nil {
+			var netErr net.Error
+			if attempt < maxRetries && errors.As(err, &netErr) && netErr.Timeout() {
+				wait := retryBackoff(attempt, 0)
+				slog.Warn("request timed out, retrying", "url", url, "attempt", attempt+1, "wait", wait)
+				time.Sleep(wait)
+				continue
+			}
+
+			return fmt.Errorf("failed to send request: %v", err)
+		}
+
+		if attempt < maxRetries && isRetryableStatus(resp.StatusCode) {
+			wait := retryBackoff(attempt, parseRetryAfter(resp.Header.Get("Retry-After")))
+			resp.Body.Close()
+			slog.Warn("retryable status, retrying", "url", url, "status", resp.StatusCode, "attempt", attempt+1, "wait", wait)
+			time.Sleep(wait)
+			continue
+		}
+
+		break
 	}
 	defer resp.Body.Close()
 
@@ -396,57 +750,3 @@ func track(msg string) (string, time.Time) {
 func duration(msg string, start time.Time) {
 	slog.Info("execution time", msg, time.Since(start))
 }
-
-func ReadJson(filePath string) []Record {
-	file, err := os.ReadFile(filePath)
-	if err != nil {
-		slog.Error("reading JSON file", "error", err)
-		return nil
-	}
-
-	var items []Record
-	if err := json.Unmarshal(file, &items); err != nil {
-		slog.Error("unmarshaling JSON", "error", err)
-		return nil
-	}
-
-	return items
-}
-
-func ReadCsv(filePath string) []Record {
-	csvFile, err := os.OpenFile(filePath, os.O_RDWR, os.ModePerm)
-	if err != nil {
-		slog.Error("opening CSV file", "error", err)
-		return nil
-	}
-	defer csvFile.Close()
-
-	var rawRecords [][]string
-
-	reader := csv.NewReader(csvFile)
-	rawRecords, err = reader.ReadAll()
-	if err != nil {
-		slog.Error("reading CSV", "error", err)
-		return nil
-	}
-
-	if len(rawRecords) < 2 {
-		slog.Error("CSV file must have headers and at least one record")
-		return nil
-	}
-
-	headers := rawRecords[0]
-	records := make([]Record, 0, len(rawRecords)-1)
-
-	for _, row := range rawRecords[1:] {
-		record := make(Record)
-		for i, value := range row {
-			if i < len(headers) {
-				record[headers[i]] = value
-			}
-		}
-		records = append(records, record)
-	}
-
-	return records
-}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Reader loads records from a file on disk in some particular format.
+type Reader interface {
+	Read(filePath string) ([]Record, error)
+}
+
+// readers maps a lowercased file extension (including the leading dot) to
+// the Reader that handles it. Register a new format here to make it
+// available to the import command.
+var readers = map[string]Reader{
+	".json": jsonReader{},
+	".csv":  csvReader{},
+	".yaml": yamlReader{},
+	".yml":  yamlReader{},
+	".toml": tomlReader{},
+}
+
+// recordsTable wraps records for formats that require a top-level key
+// rather than supporting a bare array, e.g. TOML.
+type recordsTable struct {
+	Records []Record `toml:"records"`
+}
+
+type jsonReader struct{}
+
+func (jsonReader) Read(filePath string) ([]Record, error) {
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading JSON file: %v", err)
+	}
+
+	var items []Record
+	if err := json.Unmarshal(file, &items); err != nil {
+		return nil, fmt.Errorf("unmarshaling JSON: %v", err)
+	}
+
+	return items, nil
+}
+
+type csvReader struct{}
+
+func (csvReader) Read(filePath string) ([]Record, error) {
+	csvFile, err := os.OpenFile(filePath, os.O_RDWR, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %v", err)
+	}
+	defer csvFile.Close()
+
+	reader := csv.NewReader(csvFile)
+	rawRecords, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %v", err)
+	}
+
+	if len(rawRecords) < 2 {
+		return nil, fmt.Errorf("CSV file must have headers and at least one record")
+	}
+
+	headers := rawRecords[0]
+	records := make([]Record, 0, len(rawRecords)-1)
+
+	for _, row := range rawRecords[1:] {
+		record := make(Record)
+		for i, value := range row {
+			if i < len(headers) {
+				record[headers[i]] = value
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+type yamlReader struct{}
+
+func (yamlReader) Read(filePath string) ([]Record, error) {
+	file, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading YAML file: %v", err)
+	}
+
+	var items []Record
+	if err := yaml.Unmarshal(file, &items); err != nil {
+		return nil, fmt.Errorf("unmarshaling YAML: %v", err)
+	}
+
+	return items, nil
+}
+
+type tomlReader struct{}
+
+func (tomlReader) Read(filePath string) ([]Record, error) {
+	var table recordsTable
+
+	if _, err := toml.DecodeFile(filePath, &table); err != nil {
+		return nil, fmt.Errorf("decoding TOML: %v", err)
+	}
+
+	return table.Records, nil
+}
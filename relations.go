@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// RelationCache holds the IDs of records in a single collection so a
+// relation field can be filled in with a local random pick instead of an
+// HTTP round-trip per record.
+type RelationCache struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// NewRelationCache returns an empty cache ready to be populated with Add.
+func NewRelationCache() *RelationCache {
+	return &RelationCache{}
+}
+
+// Add records an id as available for future random picks.
+func (c *RelationCache) Add(id string) {
+	if id == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ids = append(c.ids, id)
+}
+
+// Random returns a locally chosen id, or false if the cache is empty.
+func (c *RelationCache) Random() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.ids) == 0 {
+		return "", false
+	}
+
+	return c.ids[rand.Intn(len(c.ids))], true
+}
+
+// RelationCacheStore lazily loads and reuses a RelationCache per
+// collection for the lifetime of a single seed run. A collection is never
+// refreshed once loaded.
+type RelationCacheStore struct {
+	mu       sync.Mutex
+	caches   map[string]*RelationCache
+	token    string
+	poolSize int
+}
+
+// NewRelationCacheStore returns a store that pages up to poolSize ids per
+// collection (0 meaning no limit, i.e. page through every record).
+func NewRelationCacheStore(token string, poolSize int) *RelationCacheStore {
+	return &RelationCacheStore{
+		caches:   make(map[string]*RelationCache),
+		token:    token,
+		poolSize: poolSize,
+	}
+}
+
+// Get returns the RelationCache for col, loading it from
+// /api/collections/{col}/records on first use.
+func (s *RelationCacheStore) Get(col string) (*RelationCache, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cache, ok := s.caches[col]; ok {
+		return cache, nil
+	}
+
+	cache, err := loadRelationCache(col, s.token, s.poolSize)
+	if err != nil {
+		return nil, err
+	}
+
+	s.caches[col] = cache
+
+	return cache, nil
+}
+
+// loadRelationCache pages through /api/collections/{col}/records?fields=id
+// with perPage=500 until every record has been seen, or until poolSize ids
+// have been collected when poolSize > 0.
+func loadRelationCache(col string, token string, poolSize int) (*RelationCache, error) {
+	const perPage = 500
+
+	cache := NewRelationCache()
+
+	for page := 1; ; page++ {
+		var respBody struct {
+			Items      []Record `json:"items"`
+			TotalPages int      `json:"totalPages"`
+		}
+
+		url := fmt.Sprintf("/collections/%s/records?fields=id&perPage=%d&page=%d", col, perPage, page)
+
+		if err := sendRequest("GET", url, token, nil, &respBody); err != nil {
+			return nil, err
+		}
+
+		for _, item := range respBody.Items {
+			if id, ok := item["id"].(string); ok {
+				cache.Add(id)
+			}
+
+			if poolSize > 0 && len(cache.ids) >= poolSize {
+				return cache, nil
+			}
+		}
+
+		if page >= respBody.TotalPages {
+			break
+		}
+	}
+
+	return cache, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestRelationCacheRandomEmpty(t *testing.T) {
+	cache := NewRelationCache()
+
+	if _, ok := cache.Random(); ok {
+		t.Error("Random() on an empty cache returned ok=true, want false")
+	}
+}
+
+func TestRelationCacheAddAndRandom(t *testing.T) {
+	cache := NewRelationCache()
+	cache.Add("rec1")
+	cache.Add("rec2")
+	cache.Add("rec3")
+
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		id, ok := cache.Random()
+		if !ok {
+			t.Fatal("Random() returned ok=false after Add")
+		}
+		seen[id] = true
+	}
+
+	for _, want := range []string{"rec1", "rec2", "rec3"} {
+		if !seen[want] {
+			t.Errorf("Random() never returned %q across 50 draws", want)
+		}
+	}
+}
+
+func TestRelationCacheAddIgnoresEmptyID(t *testing.T) {
+	cache := NewRelationCache()
+	cache.Add("")
+
+	if _, ok := cache.Random(); ok {
+		t.Error("Add(\"\") should not make the cache non-empty")
+	}
+}
+
+func TestRelationCacheStoreReusesLoadedCache(t *testing.T) {
+	store := NewRelationCacheStore("", 0)
+
+	preloaded := NewRelationCache()
+	preloaded.Add("existing-id")
+	store.caches["posts"] = preloaded
+
+	cache, err := store.Get("posts")
+	if err != nil {
+		t.Fatalf("Get returned unexpected error: %v", err)
+	}
+
+	if cache != preloaded {
+		t.Error("Get did not return the already-cached RelationCache for a known collection")
+	}
+
+	id, ok := cache.Random()
+	if !ok || id != "existing-id" {
+		t.Errorf("Get returned a cache that lost its preloaded id: got (%q, %v)", id, ok)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetries is the number of retry attempts sendRequest makes for a
+// retryable failure before giving up. Configured once via configureRetries.
+var maxRetries = 3
+
+// configureRetries sets how many times sendRequest retries a retryable
+// failure.
+func configureRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+
+	maxRetries = n
+}
+
+// isRetryableStatus reports whether status is one PocketBase can return
+// transiently under load: rate limiting or upstream/write contention.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryBackoff returns how long to wait before the next attempt. It
+// honors a Retry-After duration when the server supplied one, otherwise
+// falls back to exponential backoff with jitter: 200ms * 2^attempt, plus
+// up to half that again at random.
+func retryBackoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	base := 200 * time.Millisecond * time.Duration(int64(1)<<uint(attempt))
+	jitter := time.Duration(rand.Int63n(int64(base)/2 + 1))
+
+	return base + jitter
+}
+
+// parseRetryAfter parses a Retry-After header value, which PocketBase (via
+// its HTTP layer) may send as either a number of seconds or an HTTP date.
+// It returns 0 if the header is absent or unparseable.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableStatus(t *testing.T) {
+	retryable := []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	for _, status := range retryable {
+		if !isRetryableStatus(status) {
+			t.Errorf("isRetryableStatus(%d) = false, want true", status)
+		}
+	}
+
+	notRetryable := []int{http.StatusOK, http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError}
+	for _, status := range notRetryable {
+		if isRetryableStatus(status) {
+			t.Errorf("isRetryableStatus(%d) = true, want false", status)
+		}
+	}
+}
+
+func TestRetryBackoffHonorsRetryAfter(t *testing.T) {
+	want := 5 * time.Second
+	if got := retryBackoff(3, want); got != want {
+		t.Errorf("retryBackoff with explicit retryAfter = %v, want %v", got, want)
+	}
+}
+
+func TestRetryBackoffExponentialWithJitter(t *testing.T) {
+	for attempt := 0; attempt < 5; attempt++ {
+		base := 200 * time.Millisecond * time.Duration(int64(1)<<uint(attempt))
+
+		for i := 0; i < 20; i++ {
+			got := retryBackoff(attempt, 0)
+			if got < base {
+				t.Fatalf("retryBackoff(%d, 0) = %v, want >= %v", attempt, got, base)
+			}
+			if got > base+base/2 {
+				t.Fatalf("retryBackoff(%d, 0) = %v, want <= %v", attempt, got, base+base/2)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfterSeconds(t *testing.T) {
+	got := parseRetryAfter("2")
+	if got != 2*time.Second {
+		t.Errorf("parseRetryAfter(\"2\") = %v, want %v", got, 2*time.Second)
+	}
+}
+
+func TestParseRetryAfterHTTPDate(t *testing.T) {
+	future := time.Now().Add(10 * time.Second).UTC()
+	header := future.Format(http.TimeFormat)
+
+	got := parseRetryAfter(header)
+	if got <= 0 || got > 10*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %v, want a positive duration <= 10s", header, got)
+	}
+}
+
+func TestParseRetryAfterPastDate(t *testing.T) {
+	past := time.Now().Add(-10 * time.Second).UTC()
+	header := past.Format(http.TimeFormat)
+
+	if got := parseRetryAfter(header); got != 0 {
+		t.Errorf("parseRetryAfter(%q) = %v, want 0 for a date already in the past", header, got)
+	}
+}
+
+func TestParseRetryAfterEmptyOrInvalid(t *testing.T) {
+	for _, header := range []string{"", "not-a-duration"} {
+		if got := parseRetryAfter(header); got != 0 {
+			t.Errorf("parseRetryAfter(%q) = %v, want 0", header, got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Writer persists records to a file on disk in some particular format.
+type Writer interface {
+	Write(filePath string, records []Record) error
+}
+
+// writers maps a lowercased file extension (including the leading dot) to
+// the Writer that handles it. Register a new format here to make it
+// available to the dump command.
+var writers = map[string]Writer{
+	".json": jsonWriter{},
+	".csv":  csvWriter{},
+	".yaml": yamlWriter{},
+	".yml":  yamlWriter{},
+	".toml": tomlWriter{},
+}
+
+type jsonWriter struct{}
+
+func (jsonWriter) Write(filePath string, records []Record) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %v", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("writing JSON file: %v", err)
+	}
+
+	return nil
+}
+
+type csvWriter struct{}
+
+func (csvWriter) Write(filePath string, records []Record) error {
+	if len(records) == 0 {
+		return fmt.Errorf("no records to write")
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("creating CSV file: %v", err)
+	}
+	defer file.Close()
+
+	seen := make(map[string]struct{})
+	headers := make([]string, 0, len(records[0]))
+	for _, r := range records {
+		for k := range r {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				headers = append(headers, k)
+			}
+		}
+	}
+	sort.Strings(headers)
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("writing CSV headers: %v", err)
+	}
+
+	for _, r := range records {
+		row := make([]string, len(headers))
+		for i, h := range headers {
+			row[i] = fmt.Sprintf("%v", r[h])
+		}
+
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("writing CSV row: %v", err)
+		}
+	}
+
+	return nil
+}
+
+type yamlWriter struct{}
+
+func (yamlWriter) Write(filePath string, records []Record) error {
+	data, err := yaml.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("marshaling YAML: %v", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("writing YAML file: %v", err)
+	}
+
+	return nil
+}
+
+type tomlWriter struct{}
+
+func (tomlWriter) Write(filePath string, records []Record) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("creating TOML file: %v", err)
+	}
+	defer file.Close()
+
+	if err := toml.NewEncoder(file).Encode(recordsTable{Records: records}); err != nil {
+		return fmt.Errorf("encoding TOML: %v", err)
+	}
+
+	return nil
+}